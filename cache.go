@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// maxCacheEntries bounds the number of bare repos kept in a repoCache's
+// on-disk directory; evict trims the least-recently-fetched entries past
+// this once a clone pushes the cache over the limit.
+const maxCacheEntries = 64
+
+// repoCache reuses bare clones across requests for the same repo URL,
+// keyed by sha1(repoURL), fetching new tags/commits into the cached copy
+// instead of re-cloning from scratch. A per-repo mutex serializes concurrent
+// requests for the same URL so they don't race on the same cache entry.
+type repoCache struct {
+	dir   string
+	ttl   time.Duration
+	locks sync.Map // repoURL -> *sync.Mutex
+}
+
+// newRepoCache returns a repoCache rooted at dir. A zero ttl means cache
+// entries never expire on their own (they're still subject to LRU eviction).
+func newRepoCache(dir string, ttl time.Duration) *repoCache {
+	return &repoCache{dir: dir, ttl: ttl}
+}
+
+func (c *repoCache) lockFor(repoURL string) *sync.Mutex {
+	mu, _ := c.locks.LoadOrStore(repoURL, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+// path returns the on-disk bare-repo directory for repoURL.
+func (c *repoCache) path(repoURL string) string {
+	sum := sha1.Sum([]byte(repoURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".git")
+}
+
+// clone returns a bare *git.Repository for repoURL, either by fetching into
+// an existing cache entry or, on a cache miss or stale/broken entry, cloning
+// fresh into the cache. The returned repoDir is the cache entry itself, not
+// a temp directory, so callers must not os.RemoveAll it.
+func (c *repoCache) clone(repoURL string) (repo *git.Repository, repoDir string, err error) {
+	mu := c.lockFor(repoURL)
+	mu.Lock()
+	defer mu.Unlock()
+
+	repoDir = c.path(repoURL)
+	if info, statErr := os.Stat(repoDir); statErr == nil && info.IsDir() {
+		if c.ttl <= 0 || time.Since(info.ModTime()) < c.ttl {
+			fetchErr := runGit(repoDir, "fetch", "--quiet", "--tags", "--prune", "origin")
+			if fetchErr == nil {
+				os.Chtimes(repoDir, time.Now(), time.Now())
+				repo, err = git.PlainOpen(repoDir)
+				return repo, repoDir, err
+			}
+			log.Printf("changelog: cache fetch for %s failed, re-cloning: %s", repoURL, fetchErr)
+		}
+		os.RemoveAll(repoDir)
+	}
+
+	if err = os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, "", err
+	}
+	if err = runGit("", "clone", "--quiet", "--bare", repoURL, repoDir); err != nil {
+		return nil, "", err
+	}
+	if evictErr := c.evict(); evictErr != nil {
+		log.Printf("changelog: cache eviction failed: %s", evictErr)
+	}
+	repo, err = git.PlainOpen(repoDir)
+	return repo, repoDir, err
+}
+
+// evict removes the least-recently-fetched cache entries once the cache
+// holds more than maxCacheEntries repos.
+func (c *repoCache) evict() error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= maxCacheEntries {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, e := range entries[:len(entries)-maxCacheEntries] {
+		if err := os.RemoveAll(filepath.Join(c.dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit runs git with args, using dir as the working directory when set
+// (repoDir == "" runs in the process's own working directory, e.g. for
+// "git clone" where the destination is itself an argument).
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	buf := &bytes.Buffer{}
+	cmd.Stderr = buf
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %s", err, buf)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// atomFeed is an Atom 1.0 feed with one entry per tagged release; the
+// synthetic Unreleased bucket is omitted since it has no stable ID or date.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// writeAtomFeed renders releases as an Atom feed, suitable for subscribing
+// to a repo's new releases in a feed reader.
+func writeAtomFeed(repo string, releases []Release, out io.Writer) error {
+	feed := atomFeed{
+		Xmlns: "http://www.w3.org/2005/Atom",
+		Title: repo,
+		ID:    "tag:changelog," + repo,
+	}
+	for _, release := range releases {
+		if release.Tag == unreleasedTag {
+			continue
+		}
+		if feed.Updated == "" {
+			feed.Updated = release.Date.Format(atomTimeLayout)
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   release.Tag,
+			ID:      repo + "@" + release.Tag,
+			Updated: release.Date.Format(atomTimeLayout),
+			Content: atomContent{Type: "text", Body: releaseContent(release)},
+		})
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// atomTimeLayout is the RFC3339 variant Atom's <updated>/<published>
+// elements require.
+const atomTimeLayout = "2006-01-02T15:04:05Z07:00"
+
+// releaseContent joins a release's commit messages (across all sections,
+// in section order) into the body of a single feed entry.
+func releaseContent(release Release) string {
+	var lines []string
+	for _, section := range changelogSections {
+		for _, commit := range release.Sections[section] {
+			lines = append(lines, "- "+FirstLine(commit.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jsonFeed is the JSON serialization of a changelog's structured Releases
+// model.
+type jsonFeed struct {
+	Repo     string        `json:"repo"`
+	Releases []jsonRelease `json:"releases"`
+}
+
+// jsonRelease is the JSON view of a Release: same shape, but with its
+// commits rendered as jsonCommit instead of the embedded go-git object.Commit.
+type jsonRelease struct {
+	Tag      string                  `json:"tag"`
+	Version  string                  `json:"version,omitempty"`
+	Date     time.Time               `json:"date"`
+	Sections map[string][]jsonCommit `json:"sections"`
+	Compare  string                  `json:"compare,omitempty"`
+}
+
+// jsonCommit is the JSON view of a DecoratedCommit. It exists because
+// object.Commit's Hash/TreeHash/ParentHashes marshal as raw byte arrays,
+// not the hex strings this tool already computes in HashHexDigest.
+type jsonCommit struct {
+	Hash         string     `json:"hash"`
+	Message      string     `json:"message"`
+	Author       string     `json:"author"`
+	Date         time.Time  `json:"date"`
+	Tags         []string   `json:"tags,omitempty"`
+	Breaking     bool       `json:"breaking,omitempty"`
+	Issues       []IssueRef `json:"issues,omitempty"`
+	PullRequests []PRRef    `json:"pullRequests,omitempty"`
+	GerritChange *ChangeRef `json:"gerritChange,omitempty"`
+}
+
+func newJSONRelease(release Release) jsonRelease {
+	sections := make(map[string][]jsonCommit, len(release.Sections))
+	for section, commits := range release.Sections {
+		jsonCommits := make([]jsonCommit, len(commits))
+		for i, c := range commits {
+			jsonCommits[i] = newJSONCommit(c)
+		}
+		sections[section] = jsonCommits
+	}
+	return jsonRelease{
+		Tag:      release.Tag,
+		Version:  release.Version,
+		Date:     release.Date,
+		Sections: sections,
+		Compare:  release.Compare,
+	}
+}
+
+func newJSONCommit(c DecoratedCommit) jsonCommit {
+	return jsonCommit{
+		Hash:         c.HashHexDigest,
+		Message:      c.Message,
+		Author:       c.Author.Name,
+		Date:         c.Author.When,
+		Tags:         c.Tags,
+		Breaking:     c.Breaking,
+		Issues:       c.Issues,
+		PullRequests: c.PullRequests,
+		GerritChange: c.GerritChange,
+	}
+}
+
+// writeJSONFeed renders releases as JSON, for programmatic consumers that
+// don't want to parse markdown.
+func writeJSONFeed(repo string, releases []Release, out io.Writer) error {
+	jsonReleases := make([]jsonRelease, len(releases))
+	for i, release := range releases {
+		jsonReleases[i] = newJSONRelease(release)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonFeed{Repo: repo, Releases: jsonReleases})
+}
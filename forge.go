@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// forgeHTTPClient bounds how long forge API calls may block a render.
+var forgeHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// IssueRef is a forge issue referenced by a commit message, e.g. via "#123"
+// or a "Fixes: #123" trailer.
+type IssueRef struct {
+	Number int
+	URL    string
+	Title  string
+	State  string
+}
+
+// PRRef is a forge pull/merge request referenced by a commit message.
+type PRRef struct {
+	Number int
+	URL    string
+	Title  string
+	State  string
+}
+
+// ChangeRef is a Gerrit change referenced by a commit's "Change-Id:" and
+// "Reviewed-on:" trailers.
+type ChangeRef struct {
+	ChangeID string
+	URL      string
+	Subject  string
+	Status   string
+}
+
+// issueRefRE matches bare issue/PR references like "#123".
+var issueRefRE = regexp.MustCompile(`#(\d+)`)
+
+// changeIDRE and reviewedOnRE match Gerrit's commit-message trailers.
+var (
+	changeIDRE   = regexp.MustCompile(`(?m)^Change-Id:\s*(\S+)$`)
+	reviewedOnRE = regexp.MustCompile(`(?m)^Reviewed-on:\s*(\S+)$`)
+)
+
+// forgeConfig identifies the forge a repo is hosted on, either configured
+// explicitly via -forge or auto-detected from the clone URL.
+type forgeConfig struct {
+	Kind  string // "github", "gitlab", or "gerrit"
+	Owner string
+	Repo  string
+}
+
+// parseForgeSpec parses a -forge flag value, e.g. "github:kevin-hanselman/changelog".
+func parseForgeSpec(spec string) (*forgeConfig, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	kind, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid -forge %q: expected KIND:OWNER/REPO", spec)
+	}
+	owner, repo, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid -forge %q: expected KIND:OWNER/REPO", spec)
+	}
+	switch kind {
+	case "github", "gitlab", "gerrit":
+	default:
+		return nil, fmt.Errorf("invalid -forge %q: unsupported kind %q", spec, kind)
+	}
+	return &forgeConfig{Kind: kind, Owner: owner, Repo: repo}, nil
+}
+
+// detectForge guesses a forgeConfig from a repo clone URL, for when -forge
+// isn't given explicitly.
+func detectForge(repoURL string) *forgeConfig {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return nil
+	}
+	host := u.Host
+	path := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok {
+		return nil
+	}
+	switch {
+	case strings.Contains(host, "github.com"):
+		return &forgeConfig{Kind: "github", Owner: owner, Repo: repo}
+	case strings.Contains(host, "gitlab.com"):
+		return &forgeConfig{Kind: "gitlab", Owner: owner, Repo: repo}
+	default:
+		return nil
+	}
+}
+
+// forgeCache caches forge API responses on disk, keyed by commit hash, so
+// repeated renders of the same history don't re-hit the forge's API. dir
+// must be its own root, not a subdirectory of a repoCache's dir: repoCache.evict
+// LRU-scans every entry under its dir with no filtering, so nesting the
+// forge cache there risks it being selected as "least recently used" and
+// wiped wholesale.
+type forgeCache struct {
+	dir string
+}
+
+func newForgeCache(dir string) *forgeCache {
+	if dir == "" {
+		return nil
+	}
+	return &forgeCache{dir: dir}
+}
+
+func (c *forgeCache) path(kind, commitHash string) string {
+	sum := sha1.Sum([]byte(kind + ":" + commitHash))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *forgeCache) load(kind, commitHash string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+	data, err := ioutil.ReadFile(c.path(kind, commitHash))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (c *forgeCache) save(kind, commitHash string, v interface{}) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(c.path(kind, commitHash), data, 0o644)
+}
+
+// scanForgeRefs extracts the issue numbers and Gerrit trailers a commit
+// message references, without calling out to any forge API. This always
+// runs, regardless of whether -forge is configured.
+func scanForgeRefs(commit *DecoratedCommit) {
+	for _, match := range issueRefRE.FindAllStringSubmatch(commit.Message, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		commit.Issues = append(commit.Issues, IssueRef{Number: n})
+	}
+
+	changeID := firstSubmatch(changeIDRE, commit.Message)
+	reviewedOn := firstSubmatch(reviewedOnRE, commit.Message)
+	if changeID != "" || reviewedOn != "" {
+		commit.GerritChange = &ChangeRef{ChangeID: changeID, URL: reviewedOn}
+	}
+}
+
+func firstSubmatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// decorateForge enriches commit's Issues/PullRequests/GerritChange with
+// titles and states fetched from cfg's forge API, using cache to avoid
+// re-fetching a commit's refs on every render. It's a no-op if cfg is nil.
+func decorateForge(commit *DecoratedCommit, cfg *forgeConfig, cache *forgeCache) {
+	scanForgeRefs(commit)
+	if cfg == nil {
+		return
+	}
+
+	switch cfg.Kind {
+	case "github":
+		decorateGitHub(commit, cfg, cache)
+	case "gitlab":
+		decorateGitLab(commit, cfg, cache)
+	case "gerrit":
+		decorateGerrit(commit, cfg, cache)
+	}
+}
+
+// githubIssue is the subset of GitHub's issue API response this tool reads.
+// A GitHub pull request is an issue with a non-nil PullRequest field.
+type githubIssue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	State       string    `json:"state"`
+	HTMLURL     string    `json:"html_url"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+func decorateGitHub(commit *DecoratedCommit, cfg *forgeConfig, cache *forgeCache) {
+	var remaining []IssueRef
+	for _, ref := range commit.Issues {
+		var issue githubIssue
+		cacheKey := fmt.Sprintf("github:%s/%s:%d", cfg.Owner, cfg.Repo, ref.Number)
+		if !cache.load(cacheKey, commit.HashHexDigest, &issue) {
+			apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", cfg.Owner, cfg.Repo, ref.Number)
+			if err := fetchJSON(apiURL, &issue); err != nil {
+				remaining = append(remaining, ref)
+				continue
+			}
+			cache.save(cacheKey, commit.HashHexDigest, &issue)
+		}
+		ref.Title, ref.State, ref.URL = issue.Title, issue.State, issue.HTMLURL
+		if issue.PullRequest != nil {
+			commit.PullRequests = append(commit.PullRequests, PRRef(ref))
+		} else {
+			remaining = append(remaining, ref)
+		}
+	}
+	commit.Issues = remaining
+}
+
+// gitlabIssue/gitlabMR are the subsets of GitLab's API responses this tool
+// reads. Unlike GitHub, GitLab numbers issues and merge requests separately,
+// so a "#123" reference is looked up as an issue first, then an MR.
+type gitlabIssue struct {
+	IID    int    `json:"iid"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+	WebURL string `json:"web_url"`
+}
+
+func decorateGitLab(commit *DecoratedCommit, cfg *forgeConfig, cache *forgeCache) {
+	project := url.QueryEscape(cfg.Owner + "/" + cfg.Repo)
+	var remaining []IssueRef
+	for _, ref := range commit.Issues {
+		var issue gitlabIssue
+		cacheKey := fmt.Sprintf("gitlab-issue:%s:%d", project, ref.Number)
+		found := cache.load(cacheKey, commit.HashHexDigest, &issue)
+		if !found {
+			apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/issues/%d", project, ref.Number)
+			if err := fetchJSON(apiURL, &issue); err == nil {
+				found = true
+				cache.save(cacheKey, commit.HashHexDigest, &issue)
+			}
+		}
+		if found {
+			ref.Title, ref.State, ref.URL = issue.Title, issue.State, issue.WebURL
+			remaining = append(remaining, ref)
+			continue
+		}
+
+		var mr gitlabIssue
+		cacheKey = fmt.Sprintf("gitlab-mr:%s:%d", project, ref.Number)
+		if !cache.load(cacheKey, commit.HashHexDigest, &mr) {
+			apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/merge_requests/%d", project, ref.Number)
+			if err := fetchJSON(apiURL, &mr); err != nil {
+				remaining = append(remaining, ref)
+				continue
+			}
+			cache.save(cacheKey, commit.HashHexDigest, &mr)
+		}
+		ref.Title, ref.State, ref.URL = mr.Title, mr.State, mr.WebURL
+		commit.PullRequests = append(commit.PullRequests, PRRef(ref))
+	}
+	commit.Issues = remaining
+}
+
+// gerritChangeInfo is the subset of Gerrit's ChangeInfo this tool reads.
+type gerritChangeInfo struct {
+	Subject string `json:"subject"`
+	Status  string `json:"status"`
+}
+
+func decorateGerrit(commit *DecoratedCommit, cfg *forgeConfig, cache *forgeCache) {
+	if commit.GerritChange == nil || commit.GerritChange.ChangeID == "" {
+		return
+	}
+	var info gerritChangeInfo
+	cacheKey := fmt.Sprintf("gerrit:%s/%s", cfg.Owner, cfg.Repo)
+	if !cache.load(cacheKey, commit.GerritChange.ChangeID, &info) {
+		apiURL := fmt.Sprintf("https://%s/changes/%s", cfg.Owner, url.PathEscape(commit.GerritChange.ChangeID))
+		if err := fetchGerritJSON(apiURL, &info); err != nil {
+			return
+		}
+		cache.save(cacheKey, commit.GerritChange.ChangeID, &info)
+	}
+	commit.GerritChange.Subject = info.Subject
+	commit.GerritChange.Status = info.Status
+}
+
+// fetchJSON GETs apiURL and decodes the JSON response body into v.
+func fetchJSON(apiURL string, v interface{}) error {
+	resp, err := forgeHTTPClient.Get(apiURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", apiURL, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// gerritMagicPrefix is the anti-XSSI prefix Gerrit prepends to every JSON
+// response body.
+var gerritMagicPrefix = []byte(")]}'\n")
+
+// fetchGerritJSON GETs apiURL and decodes a Gerrit JSON response, which is
+// prefixed with gerritMagicPrefix to guard against XSSI.
+func fetchGerritJSON(apiURL string, v interface{}) error {
+	resp, err := forgeHTTPClient.Get(apiURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", apiURL, resp.Status)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes.TrimPrefix(buf.Bytes(), gerritMagicPrefix), v)
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestParseForgeSpec(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    *forgeConfig
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "github:kevin-hanselman/changelog", want: &forgeConfig{Kind: "github", Owner: "kevin-hanselman", Repo: "changelog"}},
+		{spec: "gitlab:owner/repo", want: &forgeConfig{Kind: "gitlab", Owner: "owner", Repo: "repo"}},
+		{spec: "gerrit:review.example.com/repo", want: &forgeConfig{Kind: "gerrit", Owner: "review.example.com", Repo: "repo"}},
+		{spec: "bogus:owner/repo", wantErr: true},
+		{spec: "github:no-slash", wantErr: true},
+		{spec: "no-colon", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := parseForgeSpec(tc.spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if (got == nil) != (tc.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+			if got != nil && *got != *tc.want {
+				t.Errorf("got %+v, want %+v", *got, *tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectForge(t *testing.T) {
+	cases := []struct {
+		repoURL string
+		want    *forgeConfig
+	}{
+		{
+			repoURL: "https://github.com/kevin-hanselman/changelog.git",
+			want:    &forgeConfig{Kind: "github", Owner: "kevin-hanselman", Repo: "changelog"},
+		},
+		{
+			repoURL: "git@github.com:kevin-hanselman/changelog.git",
+			want:    nil, // scp-like syntax doesn't parse as a URL with a Host
+		},
+		{
+			repoURL: "https://gitlab.com/owner/repo",
+			want:    &forgeConfig{Kind: "gitlab", Owner: "owner", Repo: "repo"},
+		},
+		{
+			repoURL: "https://example.com/owner/repo",
+			want:    nil,
+		},
+		{
+			repoURL: "/local/path/to/repo",
+			want:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.repoURL, func(t *testing.T) {
+			got := detectForge(tc.repoURL)
+			if (got == nil) != (tc.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+			if got != nil && *got != *tc.want {
+				t.Errorf("got %+v, want %+v", *got, *tc.want)
+			}
+		})
+	}
+}
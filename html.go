@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+
+	"github.com/gomarkdown/markdown"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// htmlShellTemplate wraps rendered changelog markdown in a standalone HTML
+// page. Syntax highlighting for fenced code blocks in commit messages is
+// done client-side with highlight.js, since gomarkdown only emits the
+// "language-*" class fenced blocks are tagged with.
+const htmlShellTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css">
+<style>
+body { font: 16px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #1a1a1a; }
+h1, h2, h3 { line-height: 1.25; }
+h2 { border-bottom: 1px solid #ddd; padding-bottom: .3rem; margin-top: 2rem; }
+code, pre { background: #f6f8fa; border-radius: 4px; }
+code { padding: .1rem .3rem; }
+pre { padding: 1rem; overflow-x: auto; }
+pre code { padding: 0; background: none; }
+a { color: #0969da; }
+</style>
+</head>
+<body>
+{{ .Body }}
+<script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/highlight.min.js"></script>
+<script>hljs.highlightAll();</script>
+</body>
+</html>
+`
+
+var htmlShell = template.Must(template.New("html-shell").Parse(htmlShellTemplate))
+
+// renderHTML converts changelog markdown into a standalone HTML page, with
+// anchor IDs on release headers (via the AutoHeadingIDs extension) so links
+// to a specific release can be shared.
+func renderHTML(title string, md []byte) ([]byte, error) {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs)
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags: mdhtml.CommonFlags | mdhtml.HrefTargetBlank,
+	})
+	body := markdown.ToHTML(md, p, renderer)
+
+	out := &bytes.Buffer{}
+	err := htmlShell.Execute(out, struct {
+		Title string
+		Body  template.HTML
+	}{Title: title, Body: template.HTML(body)})
+	return out.Bytes(), err
+}
+
+// writeChangelogHTML renders the same changelog writeChangelog would, but
+// piped through renderHTML into a full HTML page instead of raw markdown.
+func writeChangelogHTML(creq ChangelogRequest, out io.Writer) error {
+	return writeChangelogHTMLPaginated(creq, nil, out)
+}
+
+// writeChangelogHTMLPaginated is writeChangelogHTML with an onPage hook, for
+// callers (the HTTP handler) that need to set Link headers before the body
+// is written.
+func writeChangelogHTMLPaginated(creq ChangelogRequest, onPage onPageFunc, out io.Writer) error {
+	md := &bytes.Buffer{}
+	if err := writeChangelog(creq, onPage, md); err != nil {
+		return err
+	}
+	rendered, err := renderHTML(creq.RepoPath, md.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(rendered)
+	return err
+}
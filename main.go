@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"encoding/hex"
 	"flag"
 	"fmt"
@@ -11,19 +10,63 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/semver"
 )
 
 // Changelog is the root data structure available to the output template.
 type Changelog struct {
-	Repo    string
-	Commits chan DecoratedCommit
+	Repo     string
+	Commits  chan DecoratedCommit
+	Releases []Release
+
+	// PrevOffset and NextOffset are the offset query parameter values for the
+	// previous/next page of commits, or -1 when there is no such page (e.g.
+	// unpaginated requests, or the first/last page).
+	PrevOffset, NextOffset int
+}
+
+// Release groups the commits reachable from a single tag (or, for
+// Release.Tag == unreleasedTag, the commits since the most recent tag) into
+// Keep-a-Changelog sections.
+type Release struct {
+	Tag      string
+	Version  string // normalized semver, e.g. "v1.2.3"; empty for Unreleased
+	Date     time.Time
+	Sections map[string][]DecoratedCommit
+	Compare  string // "<previous tag>...<tag>", empty when there is no previous tag
+}
+
+// unreleasedTag is the synthetic Release.Tag for commits made after the
+// newest tag.
+const unreleasedTag = "Unreleased"
+
+// changelogSections lists the Keep-a-Changelog sections in the order they
+// should be rendered.
+var changelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// conventionalCommitRE matches a Conventional Commits header, e.g.
+// "feat(parser): support nested tags".
+var conventionalCommitRE = regexp.MustCompile(`^(\w+)(\([^)]+\))?(!)?:\s*(.*)$`)
+
+// conventionalCommitSections maps a Conventional Commits type to the
+// Keep-a-Changelog section its commits should be bucketed into. Types absent
+// from this map (chore, merge, style, test, build, ci, ...) are skipped.
+var conventionalCommitSections = map[string]string{
+	"feat":     "Added",
+	"fix":      "Fixed",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"docs":     "Changed",
 }
 
 // DecoratedCommit is a go-git Commit struct with additional metadata.
@@ -31,6 +74,136 @@ type DecoratedCommit struct {
 	object.Commit
 	HashHexDigest string
 	Tags          []string
+	Breaking      bool
+
+	// Issues, PullRequests, and GerritChange are populated from the commit
+	// message by decorateForge: Issues and PullRequests from "#123"-style
+	// references (disambiguated and enriched via the configured forge's API
+	// when one is set), GerritChange from "Change-Id:"/"Reviewed-on:" trailers.
+	Issues       []IssueRef
+	PullRequests []PRRef
+	GerritChange *ChangeRef
+}
+
+// conventionalCommitSection returns the Keep-a-Changelog section msg's
+// Conventional Commits type maps to, and whether msg should be included in a
+// grouped changelog at all. A BREAKING CHANGE footer or a "!" before the
+// colon (e.g. "feat!:") always routes the commit to "Changed" and reports
+// breaking as true, regardless of its declared section.
+func conventionalCommitSection(msg string) (section string, breaking, ok bool) {
+	firstLine := msg
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		firstLine = msg[:i]
+	}
+	breaking = strings.Contains(msg, "BREAKING CHANGE:")
+
+	match := conventionalCommitRE.FindStringSubmatch(firstLine)
+	if match == nil {
+		return "", breaking, false
+	}
+	commitType, bang := match[1], match[3]
+	if bang == "!" {
+		breaking = true
+	}
+	if breaking {
+		return "Changed", true, true
+	}
+	section, ok = conventionalCommitSections[commitType]
+	return section, breaking, ok
+}
+
+// bestTag picks the tag to represent a release when a commit carries more
+// than one, preferring the one that looks like semver.
+func bestTag(tags []string) string {
+	for _, tag := range tags {
+		if semver.IsValid(normalizeSemver(tag)) {
+			return tag
+		}
+	}
+	return tags[0]
+}
+
+// normalizeSemver prefixes tag with "v" if needed, since golang.org/x/mod/semver
+// requires the leading "v" that bare tags like "1.2.3" omit.
+func normalizeSemver(tag string) string {
+	if strings.HasPrefix(tag, "v") {
+		return tag
+	}
+	return "v" + tag
+}
+
+// groupReleases buckets commits (as produced by the commit iterator, newest
+// first) into Releases ordered newest-to-oldest, with any commits made since
+// the newest tag collected into an Unreleased release. Tagged releases are
+// then sorted by semver rather than trusting the commit graph, since history
+// with multiple branches doesn't walk tags in version order.
+func groupReleases(commits []DecoratedCommit) []Release {
+	var tagged []Release
+	var unreleased *Release
+	var bucket []DecoratedCommit
+	seenTag := false
+	for _, c := range commits {
+		if !seenTag && len(c.Tags) > 0 {
+			seenTag = true
+			if len(bucket) > 0 {
+				release := newRelease(unreleasedTag, time.Time{}, bucket)
+				unreleased = &release
+				bucket = nil
+			}
+		}
+		bucket = append(bucket, c)
+		if len(c.Tags) > 0 {
+			tagged = append(tagged, newRelease(bestTag(c.Tags), c.Author.When, bucket))
+			bucket = nil
+		}
+	}
+	if !seenTag && len(bucket) > 0 {
+		release := newRelease(unreleasedTag, time.Time{}, bucket)
+		unreleased = &release
+	}
+
+	sort.SliceStable(tagged, func(i, j int) bool {
+		return semver.Compare(normalizeSemver(tagged[i].Tag), normalizeSemver(tagged[j].Tag)) > 0
+	})
+
+	var releases []Release
+	if unreleased != nil {
+		releases = append(releases, *unreleased)
+	}
+	releases = append(releases, tagged...)
+
+	for i := range releases {
+		if releases[i].Tag == unreleasedTag {
+			continue
+		}
+		if semver.IsValid(normalizeSemver(releases[i].Tag)) {
+			releases[i].Version = normalizeSemver(releases[i].Tag)
+		}
+		if i+1 < len(releases) && releases[i+1].Tag != unreleasedTag {
+			releases[i].Compare = fmt.Sprintf("%s...%s", releases[i+1].Tag, releases[i].Tag)
+		}
+	}
+	return releases
+}
+
+// newRelease builds a Release from the commits made under tag, skipping
+// chores/merges and anything else conventionalCommitSection doesn't
+// recognize.
+func newRelease(tag string, date time.Time, commits []DecoratedCommit) Release {
+	release := Release{
+		Tag:      tag,
+		Date:     date,
+		Sections: make(map[string][]DecoratedCommit),
+	}
+	for _, c := range commits {
+		section, breaking, ok := conventionalCommitSection(c.Message)
+		if !ok {
+			continue
+		}
+		c.Breaking = breaking
+		release.Sections[section] = append(release.Sections[section], c)
+	}
+	return release
 }
 
 const defaultTemplate = `# {{ .Repo }}
@@ -41,16 +214,85 @@ const defaultTemplate = `# {{ .Repo }}
 {{ else }}{{ end }}
 #### ` + "`{{ slice .HashHexDigest 0 7 }}`" + ` {{ .Message }}{{ end }}`
 
+// defaultGroupedTemplate renders Changelog.Releases as a Keep-a-Changelog
+// document. It's used when -group is set and -template is not.
+const defaultGroupedTemplate = `# {{ .Repo }}
+{{ range .Releases }}
+## {{ if eq .Tag "Unreleased" }}[Unreleased]{{ else }}[{{ .Tag }}]{{ if .Compare }}({{ .Compare }}){{ end }} - {{ .Date.Format "2006-01-02" }}{{ end }}
+{{ $release := . }}{{ range $section := ChangelogSections }}{{ with index $release.Sections $section }}
+### {{ $section }}
+{{ range . }}- {{ FirstLine .Message }}{{ if .Breaking }} **(BREAKING)**{{ end }} (` + "`{{ slice .HashHexDigest 0 7 }}`" + `){{ end }}
+{{ end }}{{ end }}{{ end }}`
+
 var (
-	onlyTag, serve, templatePath string
-	maxRevs                      int
+	onlyTag, serve, templatePath, cacheDir, since, forgeSpec, format string
+	maxRevs, offset, limit                                           int
+	group, htmlOutput                                                bool
+	cacheTTL                                                         time.Duration
 )
 
+// defaultCacheDir returns $XDG_CACHE_HOME/changelog (or the OS equivalent),
+// falling back to "" (caching disabled) if it can't be determined.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "changelog")
+}
+
 func init() {
 	flag.StringVar(&serve, "http", "", "serves over HTTP at the given address")
 	flag.StringVar(&onlyTag, "tag", "", "show the changelog for only the given tag")
 	flag.StringVar(&templatePath, "template", "", "load the output template from the given file")
 	flag.IntVar(&maxRevs, "max-revs", 0, "max versions to show before exiting")
+	flag.BoolVar(&group, "group", false, "group commits into semver releases with Keep-a-Changelog sections")
+	flag.StringVar(&cacheDir, "cache-dir", defaultCacheDir(), "directory to cache bare clones in; empty disables caching")
+	flag.DurationVar(&cacheTTL, "cache-ttl", 0, "max age of a cached clone before it's re-cloned instead of fetched; 0 never expires")
+	flag.BoolVar(&htmlOutput, "html", false, "render the changelog as a standalone HTML page instead of raw markdown")
+	flag.IntVar(&offset, "offset", 0, "number of commits to skip before rendering")
+	flag.IntVar(&limit, "limit", 0, "max commits to render; 0 means unlimited")
+	flag.StringVar(&since, "since", "", "skip commits newer than this tag or date (RFC3339 or YYYY-MM-DD)")
+	flag.StringVar(&forgeSpec, "forge", "", "forge to cross-link issues/PRs/changes against, e.g. github:owner/repo; auto-detected from the repo URL if unset")
+	flag.StringVar(&format, "format", "markdown", "output format: markdown, atom, or json")
+}
+
+// contentTypeForFormat returns the Content-Type header for a resolved
+// output format ("atom" or "json"; markdown is handled by wantsHTML/the
+// caller instead, since it can additionally be wrapped as HTML).
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "atom":
+		return "application/atom+xml; charset=utf-8"
+	case "json":
+		return "application/json; charset=utf-8"
+	default:
+		return ""
+	}
+}
+
+// resolveFormat picks an output format for req: an explicit "format" query
+// parameter wins, then Accept header negotiation, then "markdown".
+func resolveFormat(req *http.Request) string {
+	if f := req.URL.Query().Get("format"); f != "" {
+		return f
+	}
+	accept := req.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/atom+xml"):
+		return "atom"
+	case strings.Contains(accept, "application/json"):
+		return "json"
+	default:
+		return "markdown"
+	}
+}
+
+// wantsHTML reports whether req should get the HTML rendering, either
+// because -html was passed or the client's Accept header prefers text/html
+// (plain curl's default "*/*" does not count).
+func wantsHTML(req *http.Request) bool {
+	return htmlOutput || strings.Contains(req.Header.Get("Accept"), "text/html")
 }
 
 func collectTags(repo *git.Repository) (map[plumbing.Hash][]string, error) {
@@ -97,7 +339,32 @@ func collectTags(repo *git.Repository) (map[plumbing.Hash][]string, error) {
 	return tagsByCommit, nil
 }
 
-func clone(repoURL, tag string) (repo *git.Repository, destDir string, err error) {
+// resolveTag resolves tagName to the hash of the commit it points at,
+// handling both annotated and lightweight tags the same way collectTags
+// does for the reverse direction (commit -> tags).
+func resolveTag(repo *git.Repository, tagName string) (plumbing.Hash, error) {
+	ref, err := repo.Tag(tagName)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	tagObj, err := repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		commit, err := tagObj.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return commit.Hash, nil
+	case plumbing.ErrObjectNotFound:
+		return ref.Hash(), nil
+	default:
+		return plumbing.ZeroHash, err
+	}
+}
+
+// cloneFresh clones repoURL into a fresh tempdir, scoped to tag if given.
+// It's the fallback used when caching is disabled (-cache-dir "").
+func cloneFresh(repoURL, tag string) (repo *git.Repository, destDir string, err error) {
 	destDir, err = ioutil.TempDir("", "changelog_")
 	if err != nil {
 		return
@@ -113,34 +380,70 @@ func clone(repoURL, tag string) (repo *git.Repository, destDir string, err error
 		args = append(args, "--branch", tag)
 	}
 	args = append(args, repoURL, destDir)
-	cmd := exec.Command("git", args...)
-
-	buf := &bytes.Buffer{}
-	cmd.Stderr = buf
 
-	if err = cmd.Run(); err != nil {
-		err = fmt.Errorf("%s: %s", err, buf)
+	if err = runGit("", args...); err != nil {
 		return
 	}
 	repo, err = git.PlainOpen(destDir)
 	return
 }
 
-func writeChangelog(
-	repoPath,
-	tag string,
-	maxRevs int,
-	tmpl *template.Template,
-	out io.Writer,
-) (err error) {
-	repo, repoDir, err := clone(repoPath, tag)
-	defer os.RemoveAll(repoDir)
+// ChangelogRequest bundles the parameters that select and shape a single
+// changelog render. It's a struct rather than positional parameters because
+// writeChangelog and its HTML wrapper both need to pass all of it through
+// unchanged, and the list keeps growing as the tool grows new modes.
+type ChangelogRequest struct {
+	RepoPath string
+	Tag      string
+	MaxRevs  int
+	Group    bool
+
+	// Offset, Limit, and Since paginate the commit walk. Limit == 0 means
+	// unlimited. Since names either a tag or a date (RFC3339 or
+	// "2006-01-02"); the walk skips everything newer than it.
+	Offset int
+	Limit  int
+	Since  string
+
+	// ForgeSpec is a -forge flag value like "github:owner/repo". Empty means
+	// auto-detect from RepoPath, which may still resolve to no forge at all
+	// (e.g. a local path or an unrecognized host).
+	ForgeSpec string
+
+	// Format is "markdown" (the default), "atom", or "json". Atom and JSON
+	// always render the full Releases model, regardless of Group.
+	Format string
+
+	Cache    *repoCache
+	Template *template.Template
+}
+
+// validFormats are the values writeChangelog accepts for ChangelogRequest.Format.
+var validFormats = map[string]bool{"": true, "markdown": true, "atom": true, "json": true}
+
+// onPage, when non-nil, is called by writeChangelog with the PrevOffset and
+// NextOffset for the page it's about to render, before any output is
+// written. HTTP handlers use this to set Link headers, which must be set
+// before the body is written.
+type onPageFunc func(prevOffset, nextOffset int)
 
+func writeChangelog(creq ChangelogRequest, onPage onPageFunc, out io.Writer) (err error) {
+	var (
+		repo    *git.Repository
+		repoDir string
+	)
+	if creq.Cache != nil {
+		repo, repoDir, err = creq.Cache.clone(creq.RepoPath)
+	} else {
+		repo, repoDir, err = cloneFresh(creq.RepoPath, creq.Tag)
+		defer os.RemoveAll(repoDir)
+	}
 	if err != nil {
 		return err
 	}
 
-	if tag != "" && maxRevs == 0 {
+	maxRevs := creq.MaxRevs
+	if creq.Tag != "" && maxRevs == 0 {
 		maxRevs = 1
 	}
 
@@ -149,20 +452,148 @@ func writeChangelog(
 		return err
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{})
+	var logOpts git.LogOptions
+	if creq.Tag != "" {
+		// The cached clone path fetches full history rather than just the
+		// branch/tag cloneFresh would scope to, so -tag has to be applied
+		// here as a Log starting point rather than relying on HEAD.
+		logOpts.From, err = resolveTag(repo, creq.Tag)
+		if err != nil {
+			return fmt.Errorf("resolving tag %q: %w", creq.Tag, err)
+		}
+	}
+
+	commitIter, err := repo.Log(&logOpts)
 	defer commitIter.Close()
 	if err != nil {
 		return err
 	}
 
+	forge, err := parseForgeSpec(creq.ForgeSpec)
+	if err != nil {
+		return err
+	}
+	if forge == nil {
+		forge = detectForge(creq.RepoPath)
+	}
+	var fCache *forgeCache
+	if creq.Cache != nil {
+		// A sibling of the repo cache's dir, not nested under it: see the
+		// forgeCache doc comment for why they can't share a root.
+		fCache = newForgeCache(creq.Cache.dir + "-forge")
+	}
+
+	decorate := func(commit *object.Commit) DecoratedCommit {
+		decorated := DecoratedCommit{
+			Commit:        *commit,
+			HashHexDigest: hex.EncodeToString(commit.Hash[:]),
+			Tags:          tagsByCommit[commit.Hash],
+		}
+		decorateForge(&decorated, forge, fCache)
+		return decorated
+	}
+
+	if !validFormats[creq.Format] {
+		return fmt.Errorf("invalid format %q: must be markdown, atom, or json", creq.Format)
+	}
+	needsReleases := creq.Group || creq.Format == "atom" || creq.Format == "json"
+
+	filter := newCommitFilter(creq.Since, creq.Offset, creq.Limit)
+
+	// A caller-supplied Limit needs to know, before any output is written,
+	// whether there's a next page (to set Link headers / NextOffset), which
+	// means looking one page ahead into a bounded buffer instead of
+	// streaming straight to the template. Grouping (and the atom/json
+	// formats, which are always grouped) needs the full (bounded by
+	// maxRevs) commit list up front to sort tags by semver, for the same
+	// reason. An Offset and/or Since with no Limit has no upper bound to
+	// look ahead to, so it stays on the streaming path below, which applies
+	// filter inline instead of buffering the rest of the walk.
+	if needsReleases || creq.Limit > 0 {
+		var commits []DecoratedCommit
+		numTaggedCommits := 0
+		for {
+			commit, err := commitIter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			include, stop := filter.next(commit, tagsByCommit)
+			if stop {
+				break
+			}
+			if !include {
+				continue
+			}
+			decoratedCommit := decorate(commit)
+			if len(decoratedCommit.Tags) > 0 {
+				numTaggedCommits++
+			}
+			if maxRevs > 0 && numTaggedCommits > maxRevs {
+				break
+			}
+			commits = append(commits, decoratedCommit)
+		}
+		if creq.Since != "" && !filter.seenSince {
+			return fmt.Errorf("since %q: no commit or tag matched it in the walked history", creq.Since)
+		}
+
+		prevOffset, nextOffset := -1, -1
+		if creq.Limit > 0 {
+			if creq.Offset > 0 {
+				prevOffset = creq.Offset - creq.Limit
+				if prevOffset < 0 {
+					prevOffset = 0
+				}
+			}
+			if filter.hasMore {
+				nextOffset = creq.Offset + creq.Limit
+			}
+		}
+		if onPage != nil {
+			onPage(prevOffset, nextOffset)
+		}
+
+		switch creq.Format {
+		case "atom":
+			return writeAtomFeed(creq.RepoPath, groupReleases(commits), out)
+		case "json":
+			return writeJSONFeed(creq.RepoPath, groupReleases(commits), out)
+		}
+
+		cl := Changelog{
+			Repo:       creq.RepoPath,
+			PrevOffset: prevOffset,
+			NextOffset: nextOffset,
+		}
+		if creq.Group {
+			cl.Releases = groupReleases(commits)
+		} else {
+			cl.Commits = make(chan DecoratedCommit, len(commits))
+			for _, c := range commits {
+				cl.Commits <- c
+			}
+			close(cl.Commits)
+		}
+		return creq.Template.Execute(out, cl)
+	}
+
+	if onPage != nil {
+		onPage(-1, -1)
+	}
+
 	cl := Changelog{
-		Repo:    repoPath,
-		Commits: make(chan DecoratedCommit, 32),
+		Repo:       creq.RepoPath,
+		Commits:    make(chan DecoratedCommit, 32),
+		PrevOffset: -1,
+		NextOffset: -1,
 	}
 
 	goErr := make(chan error)
 	go func() {
-		goErr <- tmpl.Execute(out, cl)
+		goErr <- creq.Template.Execute(out, cl)
 		close(goErr)
 	}()
 
@@ -175,13 +606,15 @@ func writeChangelog(
 		if err != nil {
 			return err
 		}
-		commitTags, hasTags := tagsByCommit[commit.Hash]
-		decoratedCommit := DecoratedCommit{
-			Commit:        *commit,
-			HashHexDigest: hex.EncodeToString(commit.Hash[:]),
-			Tags:          commitTags,
+		include, stop := filter.next(commit, tagsByCommit)
+		if stop {
+			break
 		}
-		if hasTags {
+		if !include {
+			continue
+		}
+		decoratedCommit := decorate(commit)
+		if len(decoratedCommit.Tags) > 0 {
 			numTaggedCommits++
 		}
 		if maxRevs > 0 && numTaggedCommits > maxRevs {
@@ -196,10 +629,19 @@ func writeChangelog(
 		}
 	}
 	close(cl.Commits)
-	return <-goErr
+	if err := <-goErr; err != nil {
+		return err
+	}
+	if creq.Since != "" && !filter.seenSince {
+		return fmt.Errorf("since %q: no commit or tag matched it in the walked history", creq.Since)
+	}
+	return nil
 }
 
-func parseRequest(req *http.Request, route string) (repoURL, tag string, maxRevs int, err error) {
+// parseRequest builds the RepoPath/Tag/MaxRevs/Offset/Limit/Since fields of
+// a ChangelogRequest from an incoming HTTP request. The Group, Cache, and
+// Template fields are left zero for the caller to fill in.
+func parseRequest(req *http.Request, route string) (creq ChangelogRequest, err error) {
 	repoPath := strings.TrimPrefix(req.URL.Path, route)
 
 	var cloneScheme string
@@ -213,21 +655,35 @@ func parseRequest(req *http.Request, route string) (repoURL, tag string, maxRevs
 
 	parts = strings.Split(repoPath, "@")
 	if len(parts) == 2 {
-		repoPath, tag = parts[0], parts[1]
+		repoPath, creq.Tag = parts[0], parts[1]
 	} else if len(parts) > 2 {
 		err = fmt.Errorf("invalid request: %v", req)
 		return
 	}
-	maxRevsStr := req.URL.Query().Get("maxRevs")
-	if maxRevsStr != "" {
-		maxRevs, err = strconv.Atoi(maxRevsStr)
+
+	query := req.URL.Query()
+	if maxRevsStr := query.Get("maxRevs"); maxRevsStr != "" {
+		if creq.MaxRevs, err = strconv.Atoi(maxRevsStr); err != nil {
+			return
+		}
 	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if creq.Offset, err = strconv.Atoi(offsetStr); err != nil {
+			return
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if creq.Limit, err = strconv.Atoi(limitStr); err != nil {
+			return
+		}
+	}
+	creq.Since = query.Get("since")
 
 	if cloneScheme == "ssh" {
 		repoPath = "git@" + repoPath
 	}
 
-	repoURL = fmt.Sprintf("%s://%s", cloneScheme, repoPath)
+	creq.RepoPath = fmt.Sprintf("%s://%s", cloneScheme, repoPath)
 	return
 }
 
@@ -236,6 +692,21 @@ func SplitLines(s string) []string {
 	return strings.Split(s, "\n")
 }
 
+// FirstLine returns the first line of a (possibly multi-line) commit
+// message, for use in grouped-release templates.
+func FirstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// ChangelogSections returns the Keep-a-Changelog sections in display order,
+// for use by templates rendering Changelog.Releases.
+func ChangelogSections() []string {
+	return changelogSections
+}
+
 func main() {
 	check := func(err error) {
 		if err != nil {
@@ -247,9 +718,17 @@ func main() {
 	var (
 		err error
 	)
-	tmpl := template.New("changelog").Funcs(template.FuncMap{"SplitLines": SplitLines})
+	tmpl := template.New("changelog").Funcs(template.FuncMap{
+		"SplitLines":        SplitLines,
+		"FirstLine":         FirstLine,
+		"ChangelogSections": ChangelogSections,
+	})
 	if templatePath == "" {
-		tmpl, err = template.New("changelog").Parse(defaultTemplate)
+		body := defaultTemplate
+		if group {
+			body = defaultGroupedTemplate
+		}
+		tmpl, err = tmpl.Parse(body)
 	} else {
 		templateContents, err := ioutil.ReadFile(templatePath)
 		check(err)
@@ -257,6 +736,11 @@ func main() {
 	}
 	check(err)
 
+	var cache *repoCache
+	if cacheDir != "" {
+		cache = newRepoCache(cacheDir, cacheTTL)
+	}
+
 	if serve == "" {
 		repoPath := flag.Arg(0)
 		if repoPath == "" {
@@ -264,24 +748,59 @@ func main() {
 			fmt.Println("No repository path specified")
 			os.Exit(1)
 		}
-		check(writeChangelog(repoPath, onlyTag, maxRevs, tmpl, os.Stdout))
+		creq := ChangelogRequest{
+			RepoPath:  repoPath,
+			Tag:       onlyTag,
+			MaxRevs:   maxRevs,
+			Group:     group,
+			Offset:    offset,
+			Limit:     limit,
+			Since:     since,
+			ForgeSpec: forgeSpec,
+			Format:    format,
+			Cache:     cache,
+			Template:  tmpl,
+		}
+		if htmlOutput && (format == "" || format == "markdown") {
+			check(writeChangelogHTML(creq, os.Stdout))
+		} else {
+			check(writeChangelog(creq, nil, os.Stdout))
+		}
 	} else {
 		primaryRoute := "/"
 		http.HandleFunc(primaryRoute, func(w http.ResponseWriter, req *http.Request) {
-			repoURL, tag, maxRevs, err := parseRequest(req, primaryRoute)
+			creq, err := parseRequest(req, primaryRoute)
 			if err != nil {
 				fmt.Fprintln(w, err)
 				log.Println(err)
 				return
 			}
+			creq.Group, creq.Cache, creq.Template, creq.ForgeSpec = group, cache, tmpl, forgeSpec
+			creq.Format = resolveFormat(req)
 			log.Printf(
-				"%#v -> repo: %#v tag: %#v maxRevs: %d\n",
+				"%#v -> repo: %#v tag: %#v maxRevs: %d offset: %d limit: %d since: %#v format: %#v\n",
 				req.URL.String(),
-				repoURL,
-				tag,
-				maxRevs,
+				creq.RepoPath,
+				creq.Tag,
+				creq.MaxRevs,
+				creq.Offset,
+				creq.Limit,
+				creq.Since,
+				creq.Format,
 			)
-			err = writeChangelog(repoURL, tag, maxRevs, tmpl, w)
+			setPageLinks := func(prevOffset, nextOffset int) {
+				setLinkHeader(w, req, prevOffset, nextOffset)
+			}
+			switch {
+			case creq.Format == "markdown" && wantsHTML(req):
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				err = writeChangelogHTMLPaginated(creq, setPageLinks, w)
+			default:
+				if ct := contentTypeForFormat(creq.Format); ct != "" {
+					w.Header().Set("Content-Type", ct)
+				}
+				err = writeChangelog(creq, setPageLinks, w)
+			}
 			if err != nil {
 				fmt.Fprintln(w, err)
 				log.Println(err)
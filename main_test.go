@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func commit(msg string, tags ...string) DecoratedCommit {
+	return DecoratedCommit{
+		Commit: object.Commit{
+			Message: msg,
+			Author:  object.Signature{When: time.Now()},
+		},
+		Tags: tags,
+	}
+}
+
+func TestGroupReleases(t *testing.T) {
+	cases := []struct {
+		name    string
+		commits []DecoratedCommit // newest first, as the commit iterator yields them
+		want    []string          // expected release tags, newest first
+	}{
+		{
+			name:    "no tags at all becomes a single Unreleased release",
+			commits: []DecoratedCommit{commit("feat: a"), commit("feat: b")},
+			want:    []string{unreleasedTag},
+		},
+		{
+			name: "commits newer than the latest tag become Unreleased, not part of it",
+			commits: []DecoratedCommit{
+				commit("feat: newer"),
+				commit("feat: tagged", "v1.1.0"),
+				commit("feat: older", "v1.0.0"),
+			},
+			want: []string{unreleasedTag, "v1.1.0", "v1.0.0"},
+		},
+		{
+			name: "tagged releases sort by semver, not commit-graph order",
+			commits: []DecoratedCommit{
+				commit("feat: c", "v1.0.0"),
+				commit("feat: b", "v2.0.0"),
+				commit("feat: a", "v1.5.0"),
+			},
+			want: []string{"v2.0.0", "v1.5.0", "v1.0.0"},
+		},
+		{
+			name:    "no unreleased section when the newest commit is tagged",
+			commits: []DecoratedCommit{commit("feat: a", "v1.0.0")},
+			want:    []string{"v1.0.0"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			releases := groupReleases(tc.commits)
+			if len(releases) != len(tc.want) {
+				t.Fatalf("got %d releases, want %d: %+v", len(releases), len(tc.want), releases)
+			}
+			for i, release := range releases {
+				if release.Tag != tc.want[i] {
+					t.Errorf("release %d: got tag %q, want %q", i, release.Tag, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupReleasesKeepsUnreleasedCommitsOutOfNextTag(t *testing.T) {
+	commits := []DecoratedCommit{
+		commit("feat: newer"),
+		commit("feat: tagged", "v1.1.0"),
+	}
+	releases := groupReleases(commits)
+	if len(releases) != 2 || releases[0].Tag != unreleasedTag {
+		t.Fatalf("expected an Unreleased release first, got %+v", releases)
+	}
+	if _, ok := releases[0].Sections["Added"]; !ok || len(releases[0].Sections["Added"]) != 1 {
+		t.Fatalf("expected the untagged commit in Unreleased's Added section, got %+v", releases[0].Sections)
+	}
+	if commits := releases[1].Sections["Added"]; len(commits) != 1 || commits[0].Message != "feat: tagged" {
+		t.Fatalf("expected v1.1.0 to contain only its own commit, got %+v", commits)
+	}
+}
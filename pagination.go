@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sinceLayouts are the formats accepted by the "since" pagination parameter
+// when it doesn't name a tag.
+var sinceLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// parseSince parses a "since" value as a date, trying each of sinceLayouts
+// in turn.
+func parseSince(since string) (time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+	for _, layout := range sinceLayouts {
+		if t, err = time.Parse(layout, since); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// commitMatchesSince reports whether commit is the first one (walking from
+// the tip) that should be included for a "since" value naming either a tag
+// or a date: a tag match is exact, a date match is the first commit at or
+// before that date.
+func commitMatchesSince(commit *object.Commit, since string, tagsByCommit map[plumbing.Hash][]string) bool {
+	for _, t := range tagsByCommit[commit.Hash] {
+		if t == since {
+			return true
+		}
+	}
+	if sinceDate, err := parseSince(since); err == nil {
+		return !commit.Author.When.After(sinceDate)
+	}
+	return false
+}
+
+// commitFilter applies "since"/"offset"/"limit" pagination to a commit
+// stream as it's walked, so callers only ever decorate the commits that
+// will actually be rendered instead of buffering the whole history.
+type commitFilter struct {
+	since         string
+	offset, limit int
+
+	seenSince bool
+	skipped   int
+	written   int
+	hasMore   bool
+}
+
+func newCommitFilter(since string, offset, limit int) *commitFilter {
+	return &commitFilter{since: since, offset: offset, limit: limit, seenSince: since == ""}
+}
+
+// next reports whether commit passes the filter (include) and whether the
+// caller should stop walking altogether (stop), e.g. because limit commits
+// have already been included.
+func (f *commitFilter) next(commit *object.Commit, tagsByCommit map[plumbing.Hash][]string) (include, stop bool) {
+	if !f.seenSince {
+		if !commitMatchesSince(commit, f.since, tagsByCommit) {
+			return false, false
+		}
+		f.seenSince = true
+	}
+	if f.skipped < f.offset {
+		f.skipped++
+		return false, false
+	}
+	if f.limit > 0 && f.written >= f.limit {
+		f.hasMore = true
+		return false, true
+	}
+	f.written++
+	return true, false
+}
+
+// setLinkHeader sets rel="prev"/rel="next" Link headers pointing back at
+// req with its offset query parameter replaced, for clients that page
+// through a changelog by following links rather than computing offsets
+// themselves.
+func setLinkHeader(w http.ResponseWriter, req *http.Request, prevOffset, nextOffset int) {
+	var links []string
+	if prevOffset >= 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(req, prevOffset)))
+	}
+	if nextOffset >= 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(req, nextOffset)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageURL returns req's URL with its offset query parameter set to offset.
+func pageURL(req *http.Request, offset int) string {
+	u := *req.URL
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
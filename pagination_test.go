@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCommitFilterOffsetAndLimit(t *testing.T) {
+	commits := []*object.Commit{{}, {}, {}, {}, {}}
+
+	f := newCommitFilter("", 1, 2)
+	var included []int
+	for i, c := range commits {
+		include, stop := f.next(c, nil)
+		if stop {
+			break
+		}
+		if include {
+			included = append(included, i)
+		}
+	}
+	if want := []int{1, 2}; !intsEqual(included, want) {
+		t.Errorf("offset=1 limit=2: got %v, want %v", included, want)
+	}
+	if !f.hasMore {
+		t.Error("expected hasMore to be true when more commits remain past the limit")
+	}
+}
+
+func TestCommitFilterSinceTag(t *testing.T) {
+	tagged := &object.Commit{Hash: plumbing.NewHash("1111111111111111111111111111111111111111")}
+	tagsByCommit := map[plumbing.Hash][]string{tagged.Hash: {"v1.0.0"}}
+	commits := []*object.Commit{{}, tagged, {}}
+
+	f := newCommitFilter("v1.0.0", 0, 0)
+	var included []int
+	for i, c := range commits {
+		include, stop := f.next(c, tagsByCommit)
+		if stop {
+			break
+		}
+		if include {
+			included = append(included, i)
+		}
+	}
+	if want := []int{1, 2}; !intsEqual(included, want) {
+		t.Errorf("since v1.0.0: got %v, want %v", included, want)
+	}
+}
+
+func TestCommitFilterSinceNeverMatchedLeavesSeenSinceFalse(t *testing.T) {
+	commits := []*object.Commit{{}, {}, {}}
+	f := newCommitFilter("does-not-exist", 0, 0)
+	for _, c := range commits {
+		include, stop := f.next(c, nil)
+		if stop {
+			break
+		}
+		if include {
+			t.Errorf("expected no commit to be included when since never matches")
+		}
+	}
+	if f.seenSince {
+		t.Error("expected seenSince to stay false when since never matches")
+	}
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}